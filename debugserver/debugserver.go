@@ -0,0 +1,54 @@
+// Package debugserver exposes Prometheus metrics, net/http/pprof, and the
+// running log-message counts on a single HTTP listener.
+package debugserver
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"code.cloudfoundry.org/route-registrar/pkg/logging"
+)
+
+// Run starts an HTTP listener at addr serving /debug/pprof, /metrics,
+// and /varz, and returns immediately; the server runs in a background
+// goroutine until the listener is closed. A nil logCounter omits /varz.
+func Run(addr string, logCounter *logging.LogCounter) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	registerPprof(mux)
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{}))
+
+	if logCounter != nil {
+		mux.HandleFunc("/varz", varzHandler(logCounter))
+	}
+
+	go http.Serve(listener, mux)
+
+	return listener, nil
+}
+
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+func varzHandler(logCounter *logging.LogCounter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"log_counts": logCounter.Counts(),
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package debugserver_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDebugserver(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Debugserver Suite")
+}
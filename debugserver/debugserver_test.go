@@ -0,0 +1,82 @@
+package debugserver_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/route-registrar/debugserver"
+	"code.cloudfoundry.org/route-registrar/pkg/logging"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		baseURL string
+		cleanup func()
+	)
+
+	AfterEach(func() {
+		if cleanup != nil {
+			cleanup()
+		}
+	})
+
+	start := func(logCounter *logging.LogCounter) {
+		listener, err := debugserver.Run("127.0.0.1:0", logCounter)
+		Expect(err).NotTo(HaveOccurred())
+
+		baseURL = fmt.Sprintf("http://%s", listener.Addr().String())
+		cleanup = func() { listener.Close() }
+	}
+
+	It("serves /metrics", func() {
+		start(nil)
+
+		resp, err := http.Get(baseURL + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("serves /debug/pprof/", func() {
+		start(nil)
+
+		resp, err := http.Get(baseURL + "/debug/pprof/")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("serves /varz with the log counter's counts when one is given", func() {
+		_, counter, err := logging.NewLogger(logging.Config{Component: "test", MinLogLevel: 0})
+		Expect(err).NotTo(HaveOccurred())
+		start(counter)
+
+		resp, err := http.Get(baseURL + "/varz")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var body struct {
+			LogCounts map[string]uint64 `json:"log_counts"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.LogCounts).NotTo(BeNil())
+	})
+
+	It("omits /varz when no log counter is given", func() {
+		start(nil)
+
+		resp, err := http.Get(baseURL + "/varz")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,79 @@
+// Package kvapi registers routes into a KV store (Consul or etcd) instead
+// of Cloud Foundry's routing API, for environments where a service mesh
+// or a custom load balancer watches the KV tree rather than speaking to
+// routing-api directly. It satisfies the same narrow api interface that
+// registrar.registrar already depends on for routingapi.RoutingAPI.
+package kvapi
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/route-registrar/config"
+	"code.cloudfoundry.org/route-registrar/pkg/metrics"
+)
+
+// KV is the minimal key/value operation set KVAPI needs from a backend
+// client. ConsulKV and EtcdKV implement it against their respective
+// clients.
+//
+//go:generate counterfeiter . KV
+type KV interface {
+	// Put writes value at key, attached to the backend's session/lease.
+	// It does not renew that session/lease; the backend keeps it alive
+	// separately.
+	Put(key string, value []byte) error
+	// Delete removes key. It must not error if the key is already gone,
+	// since the backend's TTL may have already expired it.
+	Delete(key string) error
+}
+
+// KVAPI publishes route registrations into a KV store under
+// "<prefix>/<route.Name>", mirroring the route's config.Route as JSON so
+// watchers can reconstruct it without talking to route-registrar.
+type KVAPI struct {
+	logger lager.Logger
+	kv     KV
+	prefix string
+}
+
+// NewKVAPI returns a KVAPI that writes entries under prefix, using kv as
+// the backend-specific client.
+func NewKVAPI(logger lager.Logger, kv KV, prefix string) *KVAPI {
+	return &KVAPI{
+		logger: logger,
+		kv:     kv,
+		prefix: prefix,
+	}
+}
+
+func (k *KVAPI) key(route config.Route) string {
+	return fmt.Sprintf("%s/%s", k.prefix, route.Name)
+}
+
+// RegisterRoute writes the route to the KV store under its key.
+func (k *KVAPI) RegisterRoute(route config.Route) error {
+	k.logger.Info("Writing route to KV store", lager.Data{"route": route, "key": k.key(route)})
+
+	value, err := marshalRoute(route)
+	if err != nil {
+		metrics.RouteRegistrations.WithLabelValues("kv", "register", metrics.Outcome(err)).Inc()
+		return err
+	}
+
+	err = k.kv.Put(k.key(route), value)
+	metrics.RouteRegistrations.WithLabelValues("kv", "register", metrics.Outcome(err)).Inc()
+	return err
+}
+
+// UnregisterRoute deletes the route's key from the KV store. Backends
+// that register the key under a session/lease TTL may also just let it
+// expire on its own, but deleting it here means an orderly shutdown
+// doesn't leave a stale entry around for the TTL window.
+func (k *KVAPI) UnregisterRoute(route config.Route) error {
+	k.logger.Info("Deleting route from KV store", lager.Data{"route": route, "key": k.key(route)})
+
+	err := k.kv.Delete(k.key(route))
+	metrics.RouteRegistrations.WithLabelValues("kv", "unregister", metrics.Outcome(err)).Inc()
+	return err
+}
@@ -0,0 +1,83 @@
+package kvapi
+
+import (
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// consulSession is the subset of *consulapi.Session NewConsulKV needs;
+// narrowed to an interface so tests can fake it without a real Consul
+// agent.
+type consulSession interface {
+	Create(entry *consulapi.SessionEntry, q *consulapi.WriteOptions) (string, *consulapi.WriteMeta, error)
+	RenewPeriodic(initialTTL string, id string, q *consulapi.WriteOptions, doneCh <-chan struct{}) error
+}
+
+// consulKVStore is the subset of *consulapi.KV ConsulKV needs.
+type consulKVStore interface {
+	Acquire(pair *consulapi.KVPair, q *consulapi.WriteOptions) (bool, *consulapi.WriteMeta, error)
+	Delete(key string, w *consulapi.WriteOptions) (*consulapi.WriteMeta, error)
+}
+
+// ConsulKV implements KV against a Consul agent, registering every Put
+// under a session with the given TTL so that if route-registrar dies
+// uncleanly the entry is reaped instead of going stale forever.
+type ConsulKV struct {
+	kv        consulKVStore
+	sessionID string
+	doneChan  chan struct{}
+}
+
+// NewConsulKV creates a Consul session with the given TTL and returns a
+// KV that writes through it. Consul does not auto-renew sessions, so
+// this also starts a background RenewPeriodic loop for as long as the
+// returned ConsulKV is in use; callers must call Close to stop it.
+// Without renewal, SessionBehaviorDelete would wipe every key held
+// under the session the first time sessionTTL elapsed, regardless of
+// whether route-registrar was still healthy.
+func NewConsulKV(client *consulapi.Client, sessionTTL time.Duration) (*ConsulKV, error) {
+	return newConsulKV(client.Session(), client.KV(), sessionTTL)
+}
+
+func newConsulKV(session consulSession, kv consulKVStore, sessionTTL time.Duration) (*ConsulKV, error) {
+	sessionID, _, err := session.Create(&consulapi.SessionEntry{
+		TTL:      sessionTTL.String(),
+		Behavior: consulapi.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ConsulKV{
+		kv:        kv,
+		sessionID: sessionID,
+		doneChan:  make(chan struct{}),
+	}
+
+	go session.RenewPeriodic(sessionTTL.String(), sessionID, nil, c.doneChan)
+
+	return c, nil
+}
+
+// Close stops renewing the Consul session. It does not destroy the
+// session itself; the session is left to expire on its own TTL once
+// renewal stops.
+func (c *ConsulKV) Close() {
+	close(c.doneChan)
+}
+
+func (c *ConsulKV) Put(key string, value []byte) error {
+	pair := &consulapi.KVPair{
+		Key:     key,
+		Value:   value,
+		Session: c.sessionID,
+	}
+	_, _, err := c.kv.Acquire(pair, nil)
+	return err
+}
+
+func (c *ConsulKV) Delete(key string) error {
+	_, err := c.kv.Delete(key, nil)
+	return err
+}
@@ -0,0 +1,162 @@
+package kvapi
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeConsulSession struct {
+	mu            sync.Mutex
+	createErr     error
+	createdEntry  *consulapi.SessionEntry
+	renewedTTL    string
+	renewedID     string
+	renewDoneChan <-chan struct{}
+	renewCalled   chan struct{}
+}
+
+func newFakeConsulSession() *fakeConsulSession {
+	return &fakeConsulSession{renewCalled: make(chan struct{}, 1)}
+}
+
+func (f *fakeConsulSession) Create(entry *consulapi.SessionEntry, q *consulapi.WriteOptions) (string, *consulapi.WriteMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.createErr != nil {
+		return "", nil, f.createErr
+	}
+	f.createdEntry = entry
+	return "session-id", nil, nil
+}
+
+func (f *fakeConsulSession) RenewPeriodic(initialTTL string, id string, q *consulapi.WriteOptions, doneCh <-chan struct{}) error {
+	f.mu.Lock()
+	f.renewedTTL = initialTTL
+	f.renewedID = id
+	f.renewDoneChan = doneCh
+	f.mu.Unlock()
+	f.renewCalled <- struct{}{}
+
+	<-doneCh
+	return nil
+}
+
+type fakeConsulKVStore struct {
+	mu           sync.Mutex
+	acquiredPair *consulapi.KVPair
+	acquireErr   error
+	deletedKey   string
+	deleteErr    error
+}
+
+func (f *fakeConsulKVStore) Acquire(pair *consulapi.KVPair, q *consulapi.WriteOptions) (bool, *consulapi.WriteMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acquiredPair = pair
+	return f.acquireErr == nil, nil, f.acquireErr
+}
+
+func (f *fakeConsulKVStore) Delete(key string, w *consulapi.WriteOptions) (*consulapi.WriteMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletedKey = key
+	return nil, f.deleteErr
+}
+
+var _ = Describe("ConsulKV", func() {
+	var (
+		session *fakeConsulSession
+		store   *fakeConsulKVStore
+	)
+
+	BeforeEach(func() {
+		session = newFakeConsulSession()
+		store = &fakeConsulKVStore{}
+	})
+
+	It("creates a session with SessionBehaviorDelete and the given TTL", func() {
+		_, err := newConsulKV(session, store, 5*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(session.createdEntry.TTL).To(Equal("5s"))
+		Expect(session.createdEntry.Behavior).To(Equal(consulapi.SessionBehaviorDelete))
+	})
+
+	It("returns the session creation error", func() {
+		session.createErr = errors.New("boom")
+
+		_, err := newConsulKV(session, store, time.Second)
+
+		Expect(err).To(MatchError("boom"))
+	})
+
+	It("starts renewing the session it just created", func() {
+		c, err := newConsulKV(session, store, 5*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		Eventually(session.renewCalled).Should(Receive())
+		Expect(session.renewedID).To(Equal("session-id"))
+		Expect(session.renewedTTL).To(Equal("5s"))
+	})
+
+	It("stops renewal when Close is called", func() {
+		c, err := newConsulKV(session, store, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(session.renewCalled).Should(Receive())
+
+		done := make(chan struct{})
+		go func() {
+			c.Close()
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed())
+		Expect(session.renewDoneChan).To(BeClosed())
+	})
+
+	It("acquires the key under the session on Put", func() {
+		c, err := newConsulKV(session, store, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		Expect(c.Put("my-key", []byte("my-value"))).To(Succeed())
+
+		Expect(store.acquiredPair.Key).To(Equal("my-key"))
+		Expect(store.acquiredPair.Value).To(Equal([]byte("my-value")))
+		Expect(store.acquiredPair.Session).To(Equal("session-id"))
+	})
+
+	It("returns the Acquire error from Put", func() {
+		store.acquireErr = errors.New("acquire failed")
+		c, err := newConsulKV(session, store, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		Expect(c.Put("my-key", []byte("my-value"))).To(MatchError("acquire failed"))
+	})
+
+	It("deletes the key on Delete", func() {
+		c, err := newConsulKV(session, store, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		Expect(c.Delete("my-key")).To(Succeed())
+		Expect(store.deletedKey).To(Equal("my-key"))
+	})
+
+	It("returns the Delete error", func() {
+		store.deleteErr = errors.New("delete failed")
+		c, err := newConsulKV(session, store, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		Expect(c.Delete("my-key")).To(MatchError("delete failed"))
+	})
+})
@@ -0,0 +1,58 @@
+package kvapi
+
+import (
+	"context"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdKV implements KV against an etcd v3 client, registering every Put
+// under a lease with the given TTL so an unclean shutdown still gets
+// cleaned up once the lease expires.
+type EtcdKV struct {
+	client  *clientv3.Client
+	leaseID clientv3.LeaseID
+	timeout time.Duration
+}
+
+// NewEtcdKV grants a lease with the given TTL and keeps it alive for the
+// lifetime of the returned EtcdKV.
+func NewEtcdKV(client *clientv3.Client, leaseTTL time.Duration) (*EtcdKV, error) {
+	lease, err := client.Grant(context.Background(), int64(leaseTTL.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	keepAlive, err := client.KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for range keepAlive {
+			// drain keep-alive responses so the client library doesn't block
+		}
+	}()
+
+	return &EtcdKV{
+		client:  client,
+		leaseID: lease.ID,
+		timeout: leaseTTL,
+	}, nil
+}
+
+func (e *EtcdKV) Put(key string, value []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	_, err := e.client.Put(ctx, key, string(value), clientv3.WithLease(e.leaseID))
+	return err
+}
+
+func (e *EtcdKV) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	_, err := e.client.Delete(ctx, key)
+	return err
+}
@@ -0,0 +1,13 @@
+package kvapi_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestKVAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KVAPI Suite")
+}
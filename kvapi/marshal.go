@@ -0,0 +1,11 @@
+package kvapi
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/route-registrar/config"
+)
+
+func marshalRoute(route config.Route) ([]byte, error) {
+	return json.Marshal(route)
+}
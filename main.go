@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	consulapi "github.com/hashicorp/consul/api"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"code.cloudfoundry.org/route-registrar/config"
+	"code.cloudfoundry.org/route-registrar/debugserver"
+	"code.cloudfoundry.org/route-registrar/healthchecker"
+	"code.cloudfoundry.org/route-registrar/kvapi"
+	"code.cloudfoundry.org/route-registrar/messagebus"
+	"code.cloudfoundry.org/route-registrar/pkg/configwatcher"
+	"code.cloudfoundry.org/route-registrar/pkg/logging"
+	"code.cloudfoundry.org/route-registrar/registrar"
+	"code.cloudfoundry.org/route-registrar/routingapi"
+
+	routing_api "code.cloudfoundry.org/routing-api"
+)
+
+var (
+	configPath = flag.String("configPath", "", "path to the route-registrar config file")
+	pidfile    = flag.String("pidfile", "", "path to write the process pid to")
+	debugAddr  = flag.String("debugAddr", "", "if set, address to serve /debug/pprof, /metrics, and /varz on")
+)
+
+// kv is the subset of registrar's api interface a KV backend satisfies;
+// declared locally so main doesn't need to import registrar's unexported
+// api type to hold an optional KVAPI.
+type kv interface {
+	RegisterRoute(route config.Route) error
+	UnregisterRoute(route config.Route) error
+}
+
+func main() {
+	flag.Parse()
+
+	logger, logCounter, err := logging.NewLogger(logging.Config{
+		Component:     "route_registrar",
+		MinLogLevel:   lager.INFO,
+		SyslogEnabled: false,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logging: %s\n", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Initializing")
+
+	clientConfig, err := config.Parse(*configPath)
+	if err != nil {
+		logger.Error("failed to parse config", err)
+		os.Exit(1)
+	}
+
+	if *pidfile != "" {
+		logger.Info("Writing pid", lager.Data{"pidfile": *pidfile})
+		if err := writePidFile(*pidfile); err != nil {
+			logger.Error("failed to write pidfile", err)
+			os.Exit(1)
+		}
+	}
+
+	if *debugAddr != "" {
+		if _, err := debugserver.Run(*debugAddr, logCounter); err != nil {
+			logger.Error("failed to start debug server", err)
+			os.Exit(1)
+		}
+	}
+
+	var kvAPI kv
+	var kvBackend kvapi.KV
+	if clientConfig.KV.Backend != "" {
+		kvBackend, err = newKVBackend(clientConfig.KV)
+		if err != nil {
+			logger.Error("failed to initialize kv backend", err)
+			os.Exit(1)
+		}
+		kvAPI = kvapi.NewKVAPI(logger, kvBackend, clientConfig.KV.Prefix)
+	}
+
+	r := registrar.NewRegistrar(
+		clientConfig,
+		healthchecker.NewHealthChecker(clock.NewClock()),
+		logger,
+		messagebus.NewMessageBus(logger),
+		newRoutingAPI(clientConfig, logger),
+		kvAPI,
+	)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	reload := make(chan config.Config)
+	watcherDone := make(chan struct{})
+	defer close(watcherDone)
+
+	watcher := configwatcher.New(*configPath, logger, config.Parse)
+	go func() {
+		if err := watcher.Run(reloadSignals, reload, watcherDone); err != nil {
+			logger.Error("config-watcher-failed", err)
+		}
+	}()
+
+	ready := make(chan struct{})
+	go func() {
+		<-ready
+		logger.Info("Running")
+	}()
+
+	runErr := r.Run(signals, reload, ready)
+
+	if closer, ok := kvBackend.(interface{ Close() }); ok {
+		closer.Close()
+	}
+
+	if runErr != nil {
+		logger.Error("registrar-run-failed", runErr)
+		os.Exit(1)
+	}
+}
+
+func newRoutingAPI(clientConfig config.Config, logger lager.Logger) *routingapi.RoutingAPI {
+	apiClient := routing_api.NewClient(clientConfig.RoutingAPI.APIURL, clientConfig.RoutingAPI.SkipSSLValidation)
+
+	return routingapi.NewRoutingAPI(
+		logger,
+		newUAAClient(clientConfig.RoutingAPI),
+		apiClient,
+		clientConfig.RoutingAPI.MaxTTL,
+		routingapi.BatchConfig{
+			BatchingEnabled: clientConfig.RoutingAPI.BatchingEnabled,
+			MaxBatchSize:    clientConfig.RoutingAPI.BatchMaxSize,
+			MaxInFlight:     clientConfig.RoutingAPI.BatchMaxInFlight,
+		},
+	)
+}
+
+// uaaTokenFetcher adapts an oauth2 client-credentials config to the
+// narrow uaaClient interface RoutingAPI depends on. forceUpdate is
+// ignored: RoutingAPI.ensureToken already decides when a fetch is
+// actually needed, so every call here is expected to hit UAA.
+type uaaTokenFetcher struct {
+	config *clientcredentials.Config
+}
+
+func (u *uaaTokenFetcher) FetchToken(ctx context.Context, forceUpdate bool) (*oauth2.Token, error) {
+	return u.config.Token(ctx)
+}
+
+func newUAAClient(cfg config.RoutingAPIConfig) *uaaTokenFetcher {
+	return &uaaTokenFetcher{
+		config: &clientcredentials.Config{
+			ClientID:     cfg.UAAClientID,
+			ClientSecret: cfg.UAAClientSecret,
+			TokenURL:     cfg.UAATokenURL,
+		},
+	}
+}
+
+func newKVBackend(cfg config.KVConfig) (kvapi.KV, error) {
+	switch cfg.Backend {
+	case "consul":
+		client, err := consulapi.NewClient(&consulapi.Config{Address: cfg.ConsulAddress})
+		if err != nil {
+			return nil, err
+		}
+		return kvapi.NewConsulKV(client, cfg.SessionTTL)
+	case "etcd":
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   cfg.EtcdEndpoints,
+			DialTimeout: 5 * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return kvapi.NewEtcdKV(client, cfg.SessionTTL)
+	default:
+		return nil, fmt.Errorf("unknown kv backend %q", cfg.Backend)
+	}
+}
+
+func writePidFile(pidfile string) error {
+	return os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
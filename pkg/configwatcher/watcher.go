@@ -0,0 +1,80 @@
+// Package configwatcher re-parses route-registrar's config file on
+// SIGHUP or whenever fsnotify sees it change, and publishes the result
+// on a reload channel.
+package configwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/route-registrar/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ParseFunc loads and validates the config file at path, the same way
+// main does at startup.
+type ParseFunc func(path string) (config.Config, error)
+
+// Watcher watches a single config file path and re-parses it on demand.
+type Watcher struct {
+	path   string
+	logger lager.Logger
+	parse  ParseFunc
+}
+
+// New returns a Watcher for the config file at path.
+func New(path string, logger lager.Logger, parse ParseFunc) *Watcher {
+	return &Watcher{
+		path:   path,
+		logger: logger,
+		parse:  parse,
+	}
+}
+
+// Run watches the config file's directory for writes and listens for
+// SIGHUP, re-parsing the config and sending it on reload each time
+// either fires. It returns when done is closed.
+func (w *Watcher) Run(signals <-chan os.Signal, reload chan<- config.Config, done <-chan struct{}) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsWatcher.Close()
+
+	if err := fsWatcher.Add(filepath.Dir(w.path)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event := <-fsWatcher.Events:
+			if filepath.Clean(event.Name) != filepath.Clean(w.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			w.reload(reload)
+		case err := <-fsWatcher.Errors:
+			w.logger.Error("config-watch-error", err)
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				w.logger.Info("Caught SIGHUP; reloading config")
+				w.reload(reload)
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+func (w *Watcher) reload(reload chan<- config.Config) {
+	newConfig, err := w.parse(w.path)
+	if err != nil {
+		w.logger.Error("config-reload-error", err)
+		return
+	}
+	reload <- newConfig
+}
@@ -0,0 +1,79 @@
+// Package healthcheck implements route-registrar's health check types
+// (script, HTTP, TCP-connect, gRPC-health-probe) and retry policy.
+package healthcheck
+
+import "time"
+
+// Result is the outcome of a single health check attempt.
+type Result int
+
+const (
+	Healthy Result = iota
+	Unhealthy
+	Errored
+)
+
+func (r Result) String() string {
+	switch r {
+	case Healthy:
+		return "healthy"
+	case Unhealthy:
+		return "unhealthy"
+	case Errored:
+		return "errored"
+	default:
+		return "unknown"
+	}
+}
+
+// Checker runs a single health check attempt for one route. A fresh
+// Checker is built per route from its config.HealthCheck, so it can
+// close over whatever per-route state it needs (a URL, an address, a
+// script path).
+type Checker interface {
+	Check() (Result, error)
+}
+
+// RetryPolicy retries a Checker against an overall deadline rather than
+// failing out on the first transient error.
+type RetryPolicy struct {
+	// Attempts is the maximum number of times to call Check. Defaults to
+	// 1 (no retries) if zero or negative.
+	Attempts int
+	// Sleep is how long to wait between attempts.
+	Sleep time.Duration
+	// Timeout is the overall deadline across all attempts; once it has
+	// passed, the most recent result is returned even if Attempts has
+	// not been exhausted.
+	Timeout time.Duration
+}
+
+// Run calls checker.Check up to Attempts times, stopping early on a
+// healthy result or once Timeout has elapsed.
+func (p RetryPolicy) Run(checker Checker) (Result, error) {
+	attempts := p.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var deadline time.Time
+	if p.Timeout > 0 {
+		deadline = time.Now().Add(p.Timeout)
+	}
+
+	var result Result
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, err = checker.Check()
+		if result == Healthy {
+			return result, err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return result, err
+		}
+		if attempt < attempts {
+			time.Sleep(p.Sleep)
+		}
+	}
+	return result, err
+}
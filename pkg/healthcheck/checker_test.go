@@ -0,0 +1,94 @@
+package healthcheck
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeChecker struct {
+	results []Result
+	errs    []error
+	calls   int
+}
+
+func (f *fakeChecker) Check() (Result, error) {
+	i := f.calls
+	if i >= len(f.results) {
+		i = len(f.results) - 1
+	}
+	f.calls++
+
+	var err error
+	if i < len(f.errs) {
+		err = f.errs[i]
+	}
+	return f.results[i], err
+}
+
+var _ = Describe("RetryPolicy", func() {
+	Describe("Run", func() {
+		It("returns immediately on a healthy result", func() {
+			checker := &fakeChecker{results: []Result{Healthy}}
+			policy := RetryPolicy{Attempts: 5}
+
+			result, err := policy.Run(checker)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(Healthy))
+			Expect(checker.calls).To(Equal(1))
+		})
+
+		It("retries until healthy", func() {
+			checker := &fakeChecker{results: []Result{Unhealthy, Unhealthy, Healthy}}
+			policy := RetryPolicy{Attempts: 5, Sleep: time.Millisecond}
+
+			result, err := policy.Run(checker)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(Healthy))
+			Expect(checker.calls).To(Equal(3))
+		})
+
+		It("stops once Attempts is exhausted", func() {
+			checker := &fakeChecker{results: []Result{Unhealthy, Unhealthy, Unhealthy}}
+			policy := RetryPolicy{Attempts: 3, Sleep: time.Millisecond}
+
+			result, _ := policy.Run(checker)
+
+			Expect(result).To(Equal(Unhealthy))
+			Expect(checker.calls).To(Equal(3))
+		})
+
+		It("stops at the deadline even with attempts remaining", func() {
+			checker := &fakeChecker{results: []Result{Unhealthy, Unhealthy, Unhealthy, Unhealthy}}
+			policy := RetryPolicy{Attempts: 100, Sleep: 20 * time.Millisecond, Timeout: 25 * time.Millisecond}
+
+			result, _ := policy.Run(checker)
+
+			Expect(result).To(Equal(Unhealthy))
+			Expect(checker.calls).To(BeNumerically("<", 100))
+		})
+
+		It("defaults to one attempt when Attempts is zero", func() {
+			checker := &fakeChecker{results: []Result{Unhealthy}}
+			policy := RetryPolicy{}
+
+			policy.Run(checker)
+
+			Expect(checker.calls).To(Equal(1))
+		})
+
+		It("propagates the last error", func() {
+			wantErr := errors.New("boom")
+			checker := &fakeChecker{results: []Result{Errored}, errs: []error{wantErr}}
+			policy := RetryPolicy{Attempts: 1}
+
+			_, err := policy.Run(checker)
+
+			Expect(err).To(Equal(wantErr))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package healthcheck
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/route-registrar/config"
+	"code.cloudfoundry.org/route-registrar/healthchecker"
+)
+
+// New builds the Checker for a route's health check configuration. It
+// returns a nil Checker (and nil error) when the route has no health
+// check configured at all, matching the original "no healthchecker
+// found for route" behavior.
+func New(hc *config.HealthCheck, scriptHealthChecker healthchecker.HealthChecker) (Checker, error) {
+	if hc == nil {
+		return nil, nil
+	}
+
+	switch hc.Type {
+	case "", "script":
+		if hc.ScriptPath == "" {
+			return nil, nil
+		}
+		return &ScriptChecker{
+			HealthChecker: scriptHealthChecker,
+			ScriptPath:    hc.ScriptPath,
+			Timeout:       hc.Timeout,
+		}, nil
+	case "http":
+		regexp, err := hc.CompiledBodyRegexp()
+		if err != nil {
+			return nil, err
+		}
+		return &HTTPChecker{
+			URL:            hc.URL,
+			ExpectedStatus: hc.ExpectedStatus,
+			BodyRegexp:     regexp,
+			Timeout:        hc.Timeout,
+		}, nil
+	case "tcp":
+		return &TCPChecker{
+			Address: hc.Address,
+			Timeout: hc.Timeout,
+		}, nil
+	case "grpc":
+		return &GRPCChecker{
+			ProbeBinary: hc.GRPCProbeBinary,
+			Address:     hc.Address,
+			Timeout:     hc.Timeout,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown health check type %q", hc.Type)
+	}
+}
+
+// RetryPolicyFor derives a RetryPolicy from a route's health check
+// config, falling back to a single attempt (no retries) when the config
+// doesn't specify one.
+func RetryPolicyFor(hc *config.HealthCheck) RetryPolicy {
+	if hc == nil {
+		return RetryPolicy{Attempts: 1}
+	}
+	return RetryPolicy{
+		Attempts: hc.RetryAttempts,
+		Sleep:    hc.RetrySleep,
+		Timeout:  hc.RetryTimeout,
+	}
+}
@@ -0,0 +1,38 @@
+package healthcheck
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// GRPCChecker shells out to the grpc_health_probe binary
+// (https://github.com/grpc-ecosystem/grpc_health_probe) to check a
+// route's gRPC health.
+type GRPCChecker struct {
+	// ProbeBinary is the path to grpc_health_probe. Defaults to
+	// "grpc_health_probe" (resolved via $PATH) when empty.
+	ProbeBinary string
+	Address     string
+	Timeout     time.Duration
+}
+
+func (c *GRPCChecker) Check() (Result, error) {
+	binary := c.ProbeBinary
+	if binary == "" {
+		binary = "grpc_health_probe"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binary, "-addr", c.Address)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return Errored, ctx.Err()
+		}
+		return Unhealthy, nil
+	}
+
+	return Healthy, nil
+}
@@ -0,0 +1,49 @@
+package healthcheck
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// HTTPChecker GETs URL and considers the route healthy when the response
+// status matches ExpectedStatus (if set) and the body matches
+// BodyRegexp (if set).
+type HTTPChecker struct {
+	URL            string
+	ExpectedStatus int
+	BodyRegexp     *regexp.Regexp
+	Timeout        time.Duration
+
+	client *http.Client
+}
+
+func (c *HTTPChecker) Check() (Result, error) {
+	client := c.client
+	if client == nil {
+		client = &http.Client{Timeout: c.Timeout}
+	}
+
+	resp, err := client.Get(c.URL)
+	if err != nil {
+		return Errored, err
+	}
+	defer resp.Body.Close()
+
+	if c.ExpectedStatus != 0 && resp.StatusCode != c.ExpectedStatus {
+		return Unhealthy, nil
+	}
+
+	if c.BodyRegexp != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return Errored, err
+		}
+		if !c.BodyRegexp.Match(body) {
+			return Unhealthy, nil
+		}
+	}
+
+	return Healthy, nil
+}
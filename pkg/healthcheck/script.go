@@ -0,0 +1,29 @@
+package healthcheck
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/route-registrar/commandrunner"
+	"code.cloudfoundry.org/route-registrar/healthchecker"
+)
+
+// ScriptChecker runs route-registrar's original health check style: an
+// arbitrary script, run fresh on every check via commandrunner.
+type ScriptChecker struct {
+	HealthChecker healthchecker.HealthChecker
+	ScriptPath    string
+	Timeout       time.Duration
+}
+
+func (c *ScriptChecker) Check() (Result, error) {
+	runner := commandrunner.NewRunner(c.ScriptPath)
+
+	healthy, err := c.HealthChecker.Check(runner, c.ScriptPath, c.Timeout)
+	if err != nil {
+		return Errored, err
+	}
+	if healthy {
+		return Healthy, nil
+	}
+	return Unhealthy, nil
+}
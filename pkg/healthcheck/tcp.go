@@ -0,0 +1,22 @@
+package healthcheck
+
+import (
+	"net"
+	"time"
+)
+
+// TCPChecker considers a route healthy if a TCP connection to Address
+// succeeds within Timeout.
+type TCPChecker struct {
+	Address string
+	Timeout time.Duration
+}
+
+func (c *TCPChecker) Check() (Result, error) {
+	conn, err := net.DialTimeout("tcp", c.Address, c.Timeout)
+	if err != nil {
+		return Unhealthy, nil
+	}
+	conn.Close()
+	return Healthy, nil
+}
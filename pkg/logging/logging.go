@@ -0,0 +1,61 @@
+// Package logging builds the route-registrar logger out of pluggable
+// sinks: stdout, an optional syslog sink, and a LogCounter.
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Config controls which sinks NewLogger wires up.
+type Config struct {
+	Component     string
+	MinLogLevel   lager.LogLevel
+	SyslogEnabled bool
+}
+
+// NewLogger builds a lager.Logger backed by a stdout sink, an optional
+// syslog sink, and a LogCounter, fed through a single
+// lager.ReconfigurableSink so the level can be changed at runtime.
+func NewLogger(cfg Config) (lager.Logger, *LogCounter, error) {
+	logger := lager.NewLogger(cfg.Component)
+
+	sinks := []lager.Sink{lager.NewWriterSink(os.Stdout, lager.DEBUG)}
+
+	if cfg.SyslogEnabled {
+		syslogSink, err := NewSyslogSink(cfg.Component)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, syslogSink)
+	}
+
+	counter := NewLogCounter()
+	sinks = append(sinks, counter)
+
+	reconfigurableSink := lager.NewReconfigurableSink(newMultiSink(sinks...), cfg.MinLogLevel)
+	logger.RegisterSink(reconfigurableSink)
+
+	handleSIGUSR2(reconfigurableSink, cfg.MinLogLevel)
+
+	return logger, counter, nil
+}
+
+// handleSIGUSR2 toggles sink between DEBUG and baseLevel on each SIGUSR2.
+func handleSIGUSR2(sink *lager.ReconfigurableSink, baseLevel lager.LogLevel) {
+	usr2Chan := make(chan os.Signal, 1)
+	signal.Notify(usr2Chan, syscall.SIGUSR2)
+
+	go func() {
+		for range usr2Chan {
+			if sink.GetMinLevel() == lager.DEBUG {
+				sink.SetMinLevel(baseLevel)
+			} else {
+				sink.SetMinLevel(lager.DEBUG)
+			}
+		}
+	}()
+}
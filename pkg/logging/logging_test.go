@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"syscall"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewLogger", func() {
+	It("returns a logger and counter wired to the configured level", func() {
+		logger, counter, err := NewLogger(Config{Component: "test", MinLogLevel: lager.INFO})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counter).NotTo(BeNil())
+
+		logger.Debug("should-be-filtered")
+		logger.Info("should-be-counted")
+
+		Expect(counter.Counts()["info"]).To(Equal(uint64(1)))
+		Expect(counter.Counts()["debug"]).To(BeZero())
+	})
+})
+
+var _ = Describe("handleSIGUSR2", func() {
+	It("toggles the sink between DEBUG and the base level on each signal", func() {
+		sink := lager.NewReconfigurableSink(newMultiSink(), lager.INFO)
+		handleSIGUSR2(sink, lager.INFO)
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		Eventually(sink.GetMinLevel).Should(Equal(lager.DEBUG))
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		Eventually(sink.GetMinLevel).Should(Equal(lager.INFO))
+	}, 2)
+})
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// LogCounter is a lager.Sink that tallies messages by level instead of
+// writing them anywhere.
+type LogCounter struct {
+	mu     sync.Mutex
+	counts map[lager.LogLevel]uint64
+}
+
+// NewLogCounter returns an empty LogCounter.
+func NewLogCounter() *LogCounter {
+	return &LogCounter{
+		counts: make(map[lager.LogLevel]uint64),
+	}
+}
+
+// Log implements lager.Sink.
+func (c *LogCounter) Log(log lager.LogFormat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[log.LogLevel]++
+}
+
+// Counts returns a snapshot keyed by level name (debug, info, error, fatal,
+// or unknown for any other level).
+func (c *LogCounter) Counts() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]uint64, len(c.counts))
+	for level, count := range c.counts {
+		snapshot[levelName(level)] = count
+	}
+	return snapshot
+}
+
+func levelName(level lager.LogLevel) string {
+	switch level {
+	case lager.DEBUG:
+		return "debug"
+	case lager.INFO:
+		return "info"
+	case lager.ERROR:
+		return "error"
+	case lager.FATAL:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// multiSink fans a single Log call out to a set of child sinks.
+type multiSink struct {
+	sinks []lager.Sink
+}
+
+func newMultiSink(sinks ...lager.Sink) lager.Sink {
+	return &multiSink{sinks: sinks}
+}
+
+func (m *multiSink) Log(log lager.LogFormat) {
+	for _, sink := range m.sinks {
+		sink.Log(log)
+	}
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogCounter", func() {
+	It("tallies messages by level", func() {
+		counter := NewLogCounter()
+
+		counter.Log(lager.LogFormat{LogLevel: lager.INFO})
+		counter.Log(lager.LogFormat{LogLevel: lager.INFO})
+		counter.Log(lager.LogFormat{LogLevel: lager.ERROR})
+
+		Expect(counter.Counts()).To(Equal(map[string]uint64{
+			"info":  2,
+			"error": 1,
+		}))
+	})
+
+	It("is safe for concurrent use", func() {
+		counter := NewLogCounter()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				counter.Log(lager.LogFormat{LogLevel: lager.DEBUG})
+			}()
+		}
+		wg.Wait()
+
+		Expect(counter.Counts()["debug"]).To(Equal(uint64(100)))
+	})
+
+	It("maps unknown levels to \"unknown\"", func() {
+		counter := NewLogCounter()
+		counter.Log(lager.LogFormat{LogLevel: lager.LogLevel(99)})
+		Expect(counter.Counts()).To(Equal(map[string]uint64{"unknown": 1}))
+	})
+})
+
+type fakeSink struct {
+	logs []lager.LogFormat
+}
+
+func (f *fakeSink) Log(log lager.LogFormat) {
+	f.logs = append(f.logs, log)
+}
+
+var _ = Describe("multiSink", func() {
+	It("fans a single Log call out to every child sink", func() {
+		a, b := &fakeSink{}, &fakeSink{}
+		sink := newMultiSink(a, b)
+
+		sink.Log(lager.LogFormat{Message: "hello"})
+
+		Expect(a.logs).To(HaveLen(1))
+		Expect(b.logs).To(HaveLen(1))
+		Expect(a.logs[0].Message).To(Equal("hello"))
+	})
+})
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"log/syslog"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// syslogSink writes lager log lines to the local syslog daemon.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink dials the local syslog daemon and returns a lager.Sink
+// that forwards every log line to it.
+func NewSyslogSink(component string) (lager.Sink, error) {
+	writer, err := syslog.New(syslog.LOG_INFO, component)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Log(log lager.LogFormat) {
+	switch log.LogLevel {
+	case lager.DEBUG:
+		s.writer.Debug(log.Message)
+	case lager.INFO:
+		s.writer.Info(log.Message)
+	case lager.ERROR:
+		s.writer.Err(log.Message)
+	case lager.FATAL:
+		s.writer.Crit(log.Message)
+	}
+}
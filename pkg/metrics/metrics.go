@@ -0,0 +1,60 @@
+// Package metrics holds the Prometheus collectors route-registrar
+// instruments its route registration and health check paths with. They
+// are registered against the default registry so debugserver can expose
+// them all off a single /metrics endpoint.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// RouteRegistrations counts RegisterRoute/UnregisterRoute calls by
+	// backend (routing_api, kv, message_bus) and outcome (success,
+	// failure).
+	RouteRegistrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "route_registrar",
+		Name:      "route_registrations_total",
+		Help:      "Count of route register/unregister calls by backend, action, and outcome.",
+	}, []string{"backend", "action", "outcome"})
+
+	// RoutingAPIDuration tracks how long calls to the routing API take.
+	RoutingAPIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "route_registrar",
+		Name:      "routing_api_request_duration_seconds",
+		Help:      "Round-trip time of routing API requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"action"})
+
+	// HealthCheckResults counts health check outcomes per route.
+	HealthCheckResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "route_registrar",
+		Name:      "healthcheck_results_total",
+		Help:      "Count of health check results by route and result.",
+	}, []string{"route", "result"})
+
+	// HealthCheckDuration tracks how long a whole health check run
+	// (all retry attempts together) takes per route.
+	HealthCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "route_registrar",
+		Name:      "healthcheck_duration_seconds",
+		Help:      "Duration of a health check run, including retries, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		RouteRegistrations,
+		RoutingAPIDuration,
+		HealthCheckResults,
+		HealthCheckDuration,
+	)
+}
+
+// Outcome maps an error to the "success"/"failure" label value used
+// across these collectors.
+func Outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,40 @@
+package metrics_test
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/route-registrar/pkg/metrics"
+)
+
+var _ = Describe("Outcome", func() {
+	It("returns \"failure\" for a non-nil error", func() {
+		Expect(metrics.Outcome(errors.New("boom"))).To(Equal("failure"))
+	})
+
+	It("returns \"success\" for a nil error", func() {
+		Expect(metrics.Outcome(nil)).To(Equal("success"))
+	})
+})
+
+var _ = Describe("collectors", func() {
+	It("registers RouteRegistrations against the default registry", func() {
+		metrics.RouteRegistrations.WithLabelValues("kv", "register", "success").Inc()
+
+		count := testutil.ToFloat64(metrics.RouteRegistrations.WithLabelValues("kv", "register", "success"))
+
+		Expect(count).To(BeNumerically(">=", 1))
+	})
+
+	It("registers HealthCheckResults against the default registry", func() {
+		metrics.HealthCheckResults.WithLabelValues("my-route", "healthy").Inc()
+
+		count := testutil.ToFloat64(metrics.HealthCheckResults.WithLabelValues("my-route", "healthy"))
+
+		Expect(count).To(BeNumerically(">=", 1))
+	})
+})
@@ -4,21 +4,41 @@ import (
 	"crypto/tls"
 	"fmt"
 	"os"
+	"reflect"
 	"time"
 
-	"code.cloudfoundry.org/route-registrar/commandrunner"
 	"code.cloudfoundry.org/route-registrar/messagebus"
 	"code.cloudfoundry.org/tlsconfig"
 	uuid "github.com/nu7hatch/gouuid"
 
 	"code.cloudfoundry.org/route-registrar/config"
 	"code.cloudfoundry.org/route-registrar/healthchecker"
+	"code.cloudfoundry.org/route-registrar/pkg/healthcheck"
+	"code.cloudfoundry.org/route-registrar/pkg/metrics"
 
 	"code.cloudfoundry.org/lager/v3"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// defaultHealthCheckWorkerPoolSize bounds how many health checks can run
+// at once, regardless of how many routes are configured.
+const defaultHealthCheckWorkerPoolSize = 10
+
+// checkJob is one route's turn to be health checked, handed off from
+// Run's scheduling loop to the worker pool. The worker reports the
+// result directly to whichever of healthyChan/unhealthyChan/errChan
+// applies, rather than routing it back through the scheduler.
+type checkJob struct {
+	route         config.Route
+	checker       healthcheck.Checker
+	retry         healthcheck.RetryPolicy
+	healthyChan   chan<- config.Route
+	unhealthyChan chan<- config.Route
+	errChan       chan<- config.Route
+}
+
 type Registrar interface {
-	Run(signals <-chan os.Signal, ready chan<- struct{}) error
+	Run(signals <-chan os.Signal, reload <-chan config.Config, ready chan<- struct{}) error
 }
 
 type api interface {
@@ -26,13 +46,35 @@ type api interface {
 	UnregisterRoute(route config.Route) error
 }
 
+// routerGroupInvalidator is implemented by api backends that cache
+// router group lookups (routingapi.RoutingAPI). registrar type-asserts
+// for it when a reload changes a route's RouterGroup, so the stale GUID
+// doesn't get reused.
+type routerGroupInvalidator interface {
+	InvalidateRouterGroup(name string)
+}
+
+// scheduledRoute is one route's health-check schedule: its Checker,
+// retry policy, and when it's next due. Run's select loop keeps one of
+// these per route, keyed by route name, and drives all of them off a
+// single timer instead of a goroutine-and-ticker per route.
+type scheduledRoute struct {
+	route   config.Route
+	checker healthcheck.Checker
+	retry   healthcheck.RetryPolicy
+	next    time.Time
+}
+
 type registrar struct {
 	logger            lager.Logger
 	config            config.Config
 	healthChecker     healthchecker.HealthChecker
 	messageBus        messagebus.MessageBus
 	routingAPI        api
+	kvAPI             api
 	privateInstanceId string
+	checkQueue        chan checkJob
+	routes            map[string]*scheduledRoute
 }
 
 func NewRegistrar(
@@ -41,6 +83,7 @@ func NewRegistrar(
 	logger lager.Logger,
 	messageBus messagebus.MessageBus,
 	routingAPI api,
+	kvAPI api,
 ) Registrar {
 	aUUID, err := uuid.NewV4()
 	if err != nil {
@@ -53,10 +96,13 @@ func NewRegistrar(
 		healthChecker:     healthChecker,
 		messageBus:        messageBus,
 		routingAPI:        routingAPI,
+		kvAPI:             kvAPI,
+		checkQueue:        make(chan checkJob, defaultHealthCheckWorkerPoolSize),
+		routes:            make(map[string]*scheduledRoute),
 	}
 }
 
-func (r *registrar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+func (r *registrar) Run(signals <-chan os.Signal, reload <-chan config.Config, ready chan<- struct{}) error {
 	var err error
 	var tlsConfig *tls.Config
 
@@ -87,23 +133,18 @@ func (r *registrar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	healthyChan := make(chan config.Route, len(r.config.Routes))
 	unhealthyChan := make(chan config.Route, len(r.config.Routes))
 
-	periodicHealthcheckCloseChans := make([]chan struct{}, len(r.config.Routes))
-
-	for i := range periodicHealthcheckCloseChans {
-		periodicHealthcheckCloseChans[i] = make(chan struct{}, len(r.config.Routes))
+	for i := 0; i < defaultHealthCheckWorkerPoolSize; i++ {
+		go r.runHealthCheckWorker()
 	}
 
-	for i, route := range r.config.Routes {
-		go r.periodicallyDetermineHealth(
-			route,
-			nohealthcheckChan,
-			errChan,
-			healthyChan,
-			unhealthyChan,
-			periodicHealthcheckCloseChans[i],
-		)
+	for _, route := range r.config.Routes {
+		r.scheduleRoute(route)
 	}
 
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	r.resetTimer(timer)
+
 	for {
 		select {
 		case route := <-nohealthcheckChan:
@@ -134,16 +175,20 @@ func (r *registrar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 			if err != nil {
 				return err
 			}
+		case newConfig := <-reload:
+			err := r.applyConfigReload(newConfig)
+			if err != nil {
+				return err
+			}
+			r.resetTimer(timer)
+		case <-timer.C:
+			r.runDueChecks(nohealthcheckChan, errChan, healthyChan, unhealthyChan)
+			r.resetTimer(timer)
 		case <-signals:
 			r.logger.Info("Received signal; shutting down")
 
-			for _, c := range periodicHealthcheckCloseChans {
-				close(c)
-			}
-
-			for _, route := range r.config.Routes {
-				err := r.unregisterRoutes(route)
-				if err != nil {
+			for _, sr := range r.routes {
+				if err := r.unregisterRoutes(sr.route); err != nil {
 					return err
 				}
 			}
@@ -152,36 +197,162 @@ func (r *registrar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	}
 }
 
-func (r registrar) periodicallyDetermineHealth(
-	route config.Route,
-	nohealthcheckChan chan<- config.Route,
-	errChan chan<- config.Route,
-	healthyChan chan<- config.Route,
-	unhealthyChan chan<- config.Route,
-	closeChan chan struct{},
-) {
-	ticker := time.NewTicker(route.RegistrationInterval)
-	defer ticker.Stop()
+// scheduleRoute builds route's Checker and records it in r.routes, due
+// one RegistrationInterval from now. A later timer tick is what actually
+// runs it; this just makes the route known to the scheduler.
+func (r *registrar) scheduleRoute(route config.Route) {
+	checker, err := healthcheck.New(route.HealthCheck, r.healthChecker)
+	if err != nil {
+		r.logger.Error("invalid health check configuration for route", err, lager.Data{"route": route})
+		return
+	}
+
+	r.routes[route.Name] = &scheduledRoute{
+		route:   route,
+		checker: checker,
+		retry:   healthcheck.RetryPolicyFor(route.HealthCheck),
+		next:    time.Now().Add(route.RegistrationInterval),
+	}
+}
 
-	for {
+// resetTimer reschedules timer to fire when the soonest-due route in
+// r.routes is next due, or leaves it stopped if there are none.
+func (r *registrar) resetTimer(timer *time.Timer) {
+	if !timer.Stop() {
 		select {
-		case <-ticker.C:
-			if route.HealthCheck == nil || route.HealthCheck.ScriptPath == "" {
-				nohealthcheckChan <- route
-			} else {
-				runner := commandrunner.NewRunner(route.HealthCheck.ScriptPath)
-				healthy, err := r.healthChecker.Check(runner, route.HealthCheck.ScriptPath, route.HealthCheck.Timeout)
-				if err != nil {
-					errChan <- route
-				} else if healthy {
-					healthyChan <- route
-				} else {
-					unhealthyChan <- route
+		case <-timer.C:
+		default:
+		}
+	}
+
+	var earliest time.Time
+	for _, sr := range r.routes {
+		if earliest.IsZero() || sr.next.Before(earliest) {
+			earliest = sr.next
+		}
+	}
+	if earliest.IsZero() {
+		return
+	}
+
+	d := time.Until(earliest)
+	if d < 0 {
+		d = 0
+	}
+	timer.Reset(d)
+}
+
+// runDueChecks enqueues a checkJob (or reports no-healthchecker) for
+// every route whose RegistrationInterval has elapsed since it was last
+// checked, and reschedules each one for its next interval. This is the
+// single scheduler that feeds the bounded worker pool, replacing the
+// previous goroutine-and-ticker per route.
+func (r *registrar) runDueChecks(nohealthcheckChan, errChan, healthyChan, unhealthyChan chan config.Route) {
+	now := time.Now()
+	for _, sr := range r.routes {
+		if sr.next.After(now) {
+			continue
+		}
+		sr.next = sr.next.Add(sr.route.RegistrationInterval)
+
+		if sr.checker == nil {
+			nohealthcheckChan <- sr.route
+			continue
+		}
+
+		r.checkQueue <- checkJob{
+			route:         sr.route,
+			checker:       sr.checker,
+			retry:         sr.retry,
+			healthyChan:   healthyChan,
+			unhealthyChan: unhealthyChan,
+			errChan:       errChan,
+		}
+	}
+}
+
+// applyConfigReload diffs newConfig.Routes against r.routes, scheduling
+// added routes, rescheduling changed routes, and unregistering removed
+// routes. Routes that are unchanged are left running untouched.
+func (r *registrar) applyConfigReload(newConfig config.Config) error {
+	r.logger.Info("Reloading configuration")
+
+	seen := make(map[string]bool, len(newConfig.Routes))
+
+	for _, route := range newConfig.Routes {
+		seen[route.Name] = true
+
+		existing, ok := r.routes[route.Name]
+		switch {
+		case !ok:
+			r.logger.Info("Adding route", lager.Data{"route": route})
+			r.scheduleRoute(route)
+		case !reflect.DeepEqual(existing.route, route):
+			r.logger.Info("Restarting changed route", lager.Data{"route": route})
+
+			if existing.route.RouterGroup != route.RouterGroup {
+				if invalidator, ok := r.routingAPI.(routerGroupInvalidator); ok {
+					invalidator.InvalidateRouterGroup(existing.route.RouterGroup)
 				}
 			}
-		case <-closeChan:
-			return
+			r.scheduleRoute(route)
+		}
+	}
+
+	for name, sr := range r.routes {
+		if seen[name] {
+			continue
+		}
+
+		r.logger.Info("Removing route", lager.Data{"route": sr.route})
+		delete(r.routes, name)
+
+		if err := r.unregisterRoutes(sr.route); err != nil {
+			return err
+		}
+	}
+
+	r.config = newConfig
+
+	return nil
+}
+
+// runHealthCheckWorker drains checkJobs off the shared checkQueue and
+// runs them, bounding the number of health checks that can be in flight
+// at once to defaultHealthCheckWorkerPoolSize regardless of how many
+// routes are configured.
+func (r registrar) runHealthCheckWorker() {
+	for job := range r.checkQueue {
+		timer := prometheus.NewTimer(metrics.HealthCheckDuration.WithLabelValues(job.route.Name))
+		result, _ := job.retry.Run(job.checker)
+		timer.ObserveDuration()
+
+		metrics.HealthCheckResults.WithLabelValues(job.route.Name, result.String()).Inc()
+
+		switch result {
+		case healthcheck.Healthy:
+			job.healthyChan <- job.route
+		case healthcheck.Unhealthy:
+			job.unhealthyChan <- job.route
+		default:
+			job.errChan <- job.route
+		}
+	}
+}
+
+// backendFor picks which registration path a route should take. Routes
+// targeting a KV backend (Consul or etcd) take priority over the
+// hardcoded TCP-vs-NATS split, so that Route.Backend can be set
+// independently of Route.Type.
+func (r registrar) backendFor(route config.Route) api {
+	switch route.Backend {
+	case "consul", "etcd":
+		return r.kvAPI
+	default:
+		if route.Type == "tcp" {
+			return r.routingAPI
 		}
+		return nil
 	}
 }
 
@@ -189,10 +360,11 @@ func (r registrar) registerRoutes(route config.Route) error {
 	r.logger.Info("Registering route", lager.Data{"route": route})
 
 	var err error
-	if route.Type == "tcp" {
-		err = r.routingAPI.RegisterRoute(route)
+	if backend := r.backendFor(route); backend != nil {
+		err = backend.RegisterRoute(route)
 	} else {
 		err = r.messageBus.SendMessage("router.register", r.config.Host, route, r.privateInstanceId)
+		metrics.RouteRegistrations.WithLabelValues("message_bus", "register", metrics.Outcome(err)).Inc()
 	}
 	if err != nil {
 		return err
@@ -207,10 +379,11 @@ func (r registrar) unregisterRoutes(route config.Route) error {
 	r.logger.Info("Unregistering route", lager.Data{"route": route})
 
 	var err error
-	if route.Type == "tcp" {
-		err = r.routingAPI.UnregisterRoute(route)
+	if backend := r.backendFor(route); backend != nil {
+		err = backend.UnregisterRoute(route)
 	} else {
 		err = r.messageBus.SendMessage("router.unregister", r.config.Host, route, r.privateInstanceId)
+		metrics.RouteRegistrations.WithLabelValues("message_bus", "unregister", metrics.Outcome(err)).Inc()
 	}
 	if err != nil {
 		return err
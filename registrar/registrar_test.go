@@ -0,0 +1,155 @@
+package registrar
+
+import (
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/route-registrar/config"
+)
+
+// fakeAPI is a minimal api implementation for asserting on register/
+// unregister calls and, where needed, router group invalidation.
+type fakeAPI struct {
+	mu                sync.Mutex
+	registered        []string
+	unregistered      []string
+	invalidatedGroups []string
+}
+
+func (f *fakeAPI) RegisterRoute(route config.Route) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registered = append(f.registered, route.Name)
+	return nil
+}
+
+func (f *fakeAPI) UnregisterRoute(route config.Route) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unregistered = append(f.unregistered, route.Name)
+	return nil
+}
+
+func (f *fakeAPI) InvalidateRouterGroup(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invalidatedGroups = append(f.invalidatedGroups, name)
+}
+
+func (f *fakeAPI) unregisteredNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.unregistered))
+	copy(out, f.unregistered)
+	return out
+}
+
+// fakeMessageBus implements the narrow slice of messagebus.MessageBus
+// that registrar actually calls.
+type fakeMessageBus struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeMessageBus) Connect(servers []config.MessageBusServer, tlsConfig *tls.Config) error {
+	return nil
+}
+
+func (f *fakeMessageBus) SendMessage(subject string, host string, route config.Route, privateInstanceId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, subject+":"+route.Name)
+	return nil
+}
+
+func (f *fakeMessageBus) Close() {}
+
+// newTestRegistrar builds a registrar for exercising applyConfigReload
+// directly. It does not start the timer loop in Run, so routes here are
+// only ever scheduled, never actually checked.
+func newTestRegistrar(routes []config.Route, kvAPI *fakeAPI) *registrar {
+	return &registrar{
+		config:     config.Config{Routes: routes},
+		logger:     lager.NewLogger("test"),
+		messageBus: &fakeMessageBus{},
+		kvAPI:      kvAPI,
+		checkQueue: make(chan checkJob, 10),
+		routes:     make(map[string]*scheduledRoute),
+	}
+}
+
+// longInterval keeps these tests well clear of needing a live timer
+// loop; applyConfigReload is exercised directly, not via Run.
+const longInterval = time.Hour
+
+var _ = Describe("applyConfigReload", func() {
+	It("schedules newly added routes", func() {
+		r := newTestRegistrar(nil, &fakeAPI{})
+
+		newConfig := config.Config{Routes: []config.Route{
+			{Name: "a", RegistrationInterval: longInterval},
+		}}
+
+		Expect(r.applyConfigReload(newConfig)).To(Succeed())
+
+		_, ok := r.routes["a"]
+		Expect(ok).To(BeTrue())
+	})
+
+	It("removes dropped routes and unregisters them", func() {
+		kv := &fakeAPI{}
+		r := newTestRegistrar([]config.Route{
+			{Name: "a", RegistrationInterval: longInterval, Backend: "consul"},
+		}, kv)
+		r.scheduleRoute(r.config.Routes[0])
+
+		newConfig := config.Config{Routes: nil}
+
+		Expect(r.applyConfigReload(newConfig)).To(Succeed())
+
+		_, ok := r.routes["a"]
+		Expect(ok).To(BeFalse())
+		Expect(kv.unregisteredNames()).To(Equal([]string{"a"}))
+	})
+
+	It("restarts changed routes and invalidates their old router group", func() {
+		routingAPI := &fakeAPI{}
+		r := newTestRegistrar([]config.Route{
+			{Name: "a", RegistrationInterval: longInterval, Type: "tcp", RouterGroup: "old-group"},
+		}, &fakeAPI{})
+		r.routingAPI = routingAPI
+		r.scheduleRoute(r.config.Routes[0])
+		original := r.routes["a"]
+
+		newConfig := config.Config{Routes: []config.Route{
+			{Name: "a", RegistrationInterval: longInterval, Type: "tcp", RouterGroup: "new-group"},
+		}}
+
+		Expect(r.applyConfigReload(newConfig)).To(Succeed())
+
+		Expect(r.routes["a"]).NotTo(BeIdenticalTo(original))
+		Expect(routingAPI.invalidatedGroups).To(Equal([]string{"old-group"}))
+	})
+
+	It("leaves unchanged routes running", func() {
+		r := newTestRegistrar([]config.Route{
+			{Name: "a", RegistrationInterval: longInterval},
+		}, &fakeAPI{})
+		r.scheduleRoute(r.config.Routes[0])
+		original := r.routes["a"]
+
+		newConfig := config.Config{Routes: []config.Route{
+			{Name: "a", RegistrationInterval: longInterval},
+		}}
+
+		Expect(r.applyConfigReload(newConfig)).To(Succeed())
+
+		Expect(r.routes["a"]).To(BeIdenticalTo(original))
+	})
+})
@@ -3,9 +3,13 @@ package routingapi
 import (
 	"context"
 	"fmt"
+	mathrand "math/rand"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/route-registrar/config"
+	"code.cloudfoundry.org/route-registrar/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/oauth2"
 
 	"code.cloudfoundry.org/routing-api/models"
@@ -21,6 +25,33 @@ type RoutingAPI struct {
 	routerGroupGUID map[string]string
 
 	routingAPIMaxTTL time.Duration
+
+	tokenMu sync.Mutex
+	token   *oauth2.Token
+
+	batcher *batcher
+}
+
+// tokenExpiryBuffer is how long before a cached token's real expiry we
+// treat it as already expired, so a request doesn't race the token
+// expiring mid-flight.
+const tokenExpiryBuffer = 30 * time.Second
+
+// BatchConfig controls how RegisterRoute coalesces upserts into a single
+// UpsertTcpRouteMappings call. A zero-value BatchConfig disables
+// batching: every RegisterRoute issues its own upsert, as before.
+type BatchConfig struct {
+	// BatchingEnabled turns batching on. The batcher never waits out a
+	// fixed window before flushing; it flushes as soon as a caller finds
+	// no flush already running, coalescing only whatever else has
+	// queued up by the time that flush starts.
+	BatchingEnabled bool
+	// MaxBatchSize caps how many mappings a single flush will take off
+	// the pending queue at once.
+	MaxBatchSize int
+	// MaxInFlight bounds how many UpsertTcpRouteMappings calls can be
+	// running at once.
+	MaxInFlight int
 }
 
 //go:generate counterfeiter . uaaClient
@@ -28,8 +59,8 @@ type uaaClient interface {
 	FetchToken(context.Context, bool) (*oauth2.Token, error)
 }
 
-func NewRoutingAPI(logger lager.Logger, uaaClient uaaClient, apiClient routing_api.Client, routingAPIMaxTTL time.Duration) *RoutingAPI {
-	return &RoutingAPI{
+func NewRoutingAPI(logger lager.Logger, uaaClient uaaClient, apiClient routing_api.Client, routingAPIMaxTTL time.Duration, batchConfig BatchConfig) *RoutingAPI {
+	r := &RoutingAPI{
 		uaaClient:       uaaClient,
 		apiClient:       apiClient,
 		logger:          logger,
@@ -37,9 +68,25 @@ func NewRoutingAPI(logger lager.Logger, uaaClient uaaClient, apiClient routing_a
 
 		routingAPIMaxTTL: routingAPIMaxTTL,
 	}
+
+	if batchConfig.BatchingEnabled {
+		r.batcher = newBatcher(r, batchConfig)
+	}
+
+	return r
 }
 
-func (r *RoutingAPI) refreshToken() error {
+// ensureToken fetches a fresh OAuth token from UAA only when the cached
+// one is missing or close to expiry, instead of refreshing on every
+// register/unregister call.
+func (r *RoutingAPI) ensureToken() error {
+	r.tokenMu.Lock()
+	defer r.tokenMu.Unlock()
+
+	if r.token != nil && time.Now().Add(tokenExpiryBuffer).Before(r.token.Expiry) {
+		return nil
+	}
+
 	r.logger.Info("refresh-token")
 	token, err := r.uaaClient.FetchToken(context.Background(), false)
 	if err != nil {
@@ -49,9 +96,18 @@ func (r *RoutingAPI) refreshToken() error {
 
 	r.logger.Debug("set-token", lager.Data{"token": token})
 	r.apiClient.SetToken(token.AccessToken)
+	r.token = token
 	return nil
 }
 
+// InvalidateRouterGroup drops the cached GUID for a router group name, so
+// the next route registered against it re-resolves the GUID from the
+// routing API. registrar calls this when a config reload changes a
+// route's RouterGroup, since the old cached GUID may no longer apply.
+func (r *RoutingAPI) InvalidateRouterGroup(name string) {
+	delete(r.routerGroupGUID, name)
+}
+
 func (r *RoutingAPI) getRouterGroupGUID(name string) (string, error) {
 	guid, exists := r.routerGroupGUID[name]
 	if exists {
@@ -115,7 +171,16 @@ func nilIfEmpty(str *string) *string {
 }
 
 func (r *RoutingAPI) RegisterRoute(route config.Route) error {
-	err := r.refreshToken()
+	timer := prometheus.NewTimer(metrics.RoutingAPIDuration.WithLabelValues("register"))
+	defer timer.ObserveDuration()
+
+	err := r.registerRoute(route)
+	metrics.RouteRegistrations.WithLabelValues("routing_api", "register", metrics.Outcome(err)).Inc()
+	return err
+}
+
+func (r *RoutingAPI) registerRoute(route config.Route) error {
+	err := r.ensureToken()
 	if err != nil {
 		return err
 	}
@@ -125,16 +190,24 @@ func (r *RoutingAPI) RegisterRoute(route config.Route) error {
 		return err
 	}
 
-	err = r.apiClient.UpsertTcpRouteMappings([]models.TcpRouteMapping{
-		routeMapping})
+	if r.batcher != nil {
+		return r.batcher.Enqueue(routeMapping)
+	}
+
+	return r.upsertWithRetry([]models.TcpRouteMapping{routeMapping})
+}
 
-	r.logger.Info("Upserted route", lager.Data{"route-mapping": routeMapping})
+func (r *RoutingAPI) UnregisterRoute(route config.Route) error {
+	timer := prometheus.NewTimer(metrics.RoutingAPIDuration.WithLabelValues("unregister"))
+	defer timer.ObserveDuration()
 
+	err := r.unregisterRoute(route)
+	metrics.RouteRegistrations.WithLabelValues("routing_api", "unregister", metrics.Outcome(err)).Inc()
 	return err
 }
 
-func (r *RoutingAPI) UnregisterRoute(route config.Route) error {
-	err := r.refreshToken()
+func (r *RoutingAPI) unregisterRoute(route config.Route) error {
+	err := r.ensureToken()
 	if err != nil {
 		return err
 	}
@@ -148,3 +221,36 @@ func (r *RoutingAPI) UnregisterRoute(route config.Route) error {
 
 	return r.apiClient.DeleteTcpRouteMappings([]models.TcpRouteMapping{routeMapping})
 }
+
+const (
+	maxUpsertAttempts = 3
+	upsertBaseBackoff = 100 * time.Millisecond
+)
+
+// upsertWithRetry calls UpsertTcpRouteMappings, retrying with jittered
+// exponential backoff if routing-api returns an error. routing-api
+// doesn't currently give us a typed way to distinguish a transient 5xx
+// from a permanent rejection, so we retry any error up to
+// maxUpsertAttempts rather than failing the whole batch on one hiccup.
+func (r *RoutingAPI) upsertWithRetry(mappings []models.TcpRouteMapping) error {
+	var err error
+	for attempt := 0; attempt < maxUpsertAttempts; attempt++ {
+		err = r.apiClient.UpsertTcpRouteMappings(mappings)
+		if err == nil {
+			r.logger.Info("Upserted routes", lager.Data{"route-mappings": mappings})
+			return nil
+		}
+
+		if attempt < maxUpsertAttempts-1 {
+			backoff := upsertBaseBackoff * time.Duration(1<<uint(attempt))
+			time.Sleep(backoff + jitter(backoff))
+		}
+	}
+
+	r.logger.Error("upsert-failed", err, lager.Data{"route-mappings": mappings})
+	return err
+}
+
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(mathrand.Int63n(int64(d)))
+}
@@ -0,0 +1,106 @@
+package routingapi
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/routing-api/models"
+)
+
+// batcher coalesces concurrently-arriving mappings into a single
+// UpsertTcpRouteMappings call. The first caller to find no flush
+// already running becomes the flusher, draining whatever has queued up
+// by the time it gets there; callers block in Enqueue until their
+// mapping's batch has flushed.
+type batcher struct {
+	api      *RoutingAPI
+	config   BatchConfig
+	inFlight chan struct{}
+
+	// upsert defaults to api.upsertWithRetry; tests override it to avoid
+	// needing a real apiClient/uaaClient.
+	upsert func([]models.TcpRouteMapping) error
+
+	mu       sync.Mutex
+	pending  []pendingMapping
+	flushing bool
+}
+
+type pendingMapping struct {
+	mapping models.TcpRouteMapping
+	result  chan error
+}
+
+func newBatcher(api *RoutingAPI, config BatchConfig) *batcher {
+	maxInFlight := config.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+
+	b := &batcher{
+		api:      api,
+		config:   config,
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+	b.upsert = api.upsertWithRetry
+	return b
+}
+
+// Enqueue adds mapping to the pending batch, flushing inline if no
+// flush is already running, and blocks until its mapping is upserted.
+func (b *batcher) Enqueue(mapping models.TcpRouteMapping) error {
+	result := make(chan error, 1)
+
+	b.mu.Lock()
+	b.pending = append(b.pending, pendingMapping{mapping: mapping, result: result})
+	shouldFlush := !b.flushing
+	if shouldFlush {
+		b.flushing = true
+	}
+	b.mu.Unlock()
+
+	if shouldFlush {
+		b.drain()
+	}
+
+	return <-result
+}
+
+// drain flushes pending in rounds of up to MaxBatchSize until it's
+// empty, so mappings that arrive while an upsert is in flight join the
+// next round instead of each issuing their own call.
+func (b *batcher) drain() {
+	for {
+		b.mu.Lock()
+		if len(b.pending) == 0 {
+			b.flushing = false
+			b.mu.Unlock()
+			return
+		}
+
+		batch := b.pending
+		if max := b.config.MaxBatchSize; max > 0 && len(batch) > max {
+			batch = batch[:max]
+			b.pending = b.pending[max:]
+		} else {
+			b.pending = nil
+		}
+		b.mu.Unlock()
+
+		b.flush(batch)
+	}
+}
+
+func (b *batcher) flush(batch []pendingMapping) {
+	b.inFlight <- struct{}{}
+	mappings := make([]models.TcpRouteMapping, len(batch))
+	for i, p := range batch {
+		mappings[i] = p.mapping
+	}
+
+	err := b.upsert(mappings)
+	<-b.inFlight
+
+	for _, p := range batch {
+		p.result <- err
+	}
+}
@@ -0,0 +1,139 @@
+package routingapi
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/routing-api/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// countingAPI stands in for RoutingAPI.upsertWithRetry, recording how
+// many mappings arrived in each call so tests can assert on coalescing.
+type countingAPI struct {
+	mu       sync.Mutex
+	calls    [][]models.TcpRouteMapping
+	blockOn  chan struct{}
+	released chan struct{}
+	release  sync.Once
+	err      error
+}
+
+func (c *countingAPI) upsert(mappings []models.TcpRouteMapping) error {
+	if c.blockOn != nil {
+		c.release.Do(func() { close(c.released) })
+		<-c.blockOn
+	}
+
+	c.mu.Lock()
+	c.calls = append(c.calls, mappings)
+	c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *countingAPI) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func newTestBatcher(config BatchConfig, api *countingAPI) *batcher {
+	b := newBatcher(&RoutingAPI{}, config)
+	b.upsert = api.upsert
+	return b
+}
+
+var _ = Describe("batcher", func() {
+	var config BatchConfig
+
+	BeforeEach(func() {
+		config = BatchConfig{BatchingEnabled: true, MaxBatchSize: 10}
+	})
+
+	Describe("Enqueue", func() {
+		It("flushes immediately for a solo caller", func() {
+			api := &countingAPI{}
+			b := newTestBatcher(config, api)
+
+			err := b.Enqueue(models.TcpRouteMapping{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(api.calls).To(HaveLen(1))
+			Expect(api.calls[0]).To(HaveLen(1))
+		})
+
+		It("coalesces callers that arrive while a flush is in flight", func() {
+			api := &countingAPI{blockOn: make(chan struct{}), released: make(chan struct{})}
+			config.MaxInFlight = 1
+			b := newTestBatcher(config, api)
+
+			var wg sync.WaitGroup
+			const n = 5
+			wg.Add(n)
+
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(b.Enqueue(models.TcpRouteMapping{})).To(Succeed())
+			}()
+
+			<-api.released
+			for i := 1; i < n; i++ {
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					Expect(b.Enqueue(models.TcpRouteMapping{})).To(Succeed())
+				}()
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(api.blockOn)
+			wg.Wait()
+
+			Expect(api.callCount()).To(BeNumerically("<", n))
+		})
+
+		It("propagates the upsert error to every caller in the batch", func() {
+			wantErr := errors.New("boom")
+			api := &countingAPI{err: wantErr}
+			b := newTestBatcher(config, api)
+
+			Expect(b.Enqueue(models.TcpRouteMapping{})).To(MatchError(wantErr))
+		})
+
+		It("caps a single flush at MaxBatchSize", func() {
+			api := &countingAPI{}
+			config.MaxBatchSize = 2
+			b := newTestBatcher(config, api)
+
+			b.mu.Lock()
+			b.pending = []pendingMapping{
+				{result: make(chan error, 1)},
+				{result: make(chan error, 1)},
+				{result: make(chan error, 1)},
+			}
+			b.mu.Unlock()
+
+			b.drain()
+
+			Expect(api.calls).To(HaveLen(2))
+			Expect(api.calls[0]).To(HaveLen(2))
+			Expect(api.calls[1]).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("newBatcher", func() {
+	It("defaults MaxInFlight to 1", func() {
+		b := newBatcher(&RoutingAPI{}, BatchConfig{BatchingEnabled: true})
+		Expect(cap(b.inFlight)).To(Equal(1))
+	})
+
+	It("honors an explicit MaxInFlight", func() {
+		b := newBatcher(&RoutingAPI{}, BatchConfig{BatchingEnabled: true, MaxInFlight: 3})
+		Expect(cap(b.inFlight)).To(Equal(3))
+	})
+})
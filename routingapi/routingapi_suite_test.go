@@ -0,0 +1,13 @@
+package routingapi_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRoutingAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RoutingAPI Suite")
+}